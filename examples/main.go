@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -36,14 +37,13 @@ func OnOffExample() {
 	onoff := ssm.NewStateMachine(off)
 	cfg := onoff.Configure(off)
 	cfg.Permit(space, on)
-	cfg.OnEnter(func() { fmt.Println("entering off ") })
-	cfg.OnExit(func() { fmt.Println("exiting off ") })
 
 	cfg = onoff.Configure(on)
 	cfg.Permit(space, off)
-	cfg.OnEnter(func() { fmt.Println("entering on ") })
-	cfg.OnExit(func() { fmt.Println("exiting on ") })
 
+	onoff.RegisterObserver(ssm.NewSlogObserver(nil))
+
+	ctx := context.Background()
 	reader := bufio.NewReader(os.Stdin)
 	for {
 		fmt.Println("current state: ", onoff.State().Name)
@@ -51,7 +51,7 @@ func OnOffExample() {
 		text, _ := reader.ReadString('\n')
 		text = strings.Replace(text, "\n", "", -1)
 
-		err := onoff.Fire(text, nil)
+		err := onoff.Fire(ctx, text)
 		if err != nil {
 			fmt.Println(err)
 		}
@@ -72,7 +72,7 @@ var (
 	Closed   = ssm.State{Name: "Closed"}
 
 	// triggers
-	Assign = ssm.Trigger{Key: "assign"}
+	Assign = ssm.NewTriggerWithParameters[string]("assign")
 	Close  = ssm.Trigger{Key: "close"}
 )
 
@@ -87,21 +87,21 @@ func NewBug(title string) *Bug {
 
 	sm := ssm.NewStateMachine(Open)
 	cfg := sm.Configure(Open)
-	cfg.Permit(Assign, Assigned)
+	ssm.PermitTyped(cfg, Assign, Assigned)
 
 	cfg = sm.Configure(Assigned)
 	cfg.SubstateOf(Open)
 	cfg.Permit(Close, Closed)
-	cfg.Permit(Assign, Assigned)
-	cfg.OnEnterFrom(Assign, func(ctx interface{}) {
-		b.Assignee = ctx.(string)
-		b.SendEmail(fmt.Sprintf("%s assigned to you", b.Title))
+	ssm.PermitTyped(cfg, Assign, Assigned)
+	ssm.OnEnterFromTyped(cfg, Assign, func(ctx context.Context, assignee string) error {
+		b.Assignee = assignee
+		return b.SendEmail(ctx, fmt.Sprintf("%s assigned to you", b.Title))
 	})
-	cfg.OnExit(func() { b.Deassigned() })
+	cfg.OnExit(func(ctx context.Context, args ...any) error { return b.Deassigned(ctx) })
 
 	cfg = sm.Configure(Closed)
-	cfg.OnEnter(func() {
-		b.SendEmail(fmt.Sprintf("%s has been closed", b.Title))
+	cfg.OnEnter(func(ctx context.Context, args ...any) error {
+		return b.SendEmail(ctx, fmt.Sprintf("%s has been closed", b.Title))
 	})
 
 	b.sm = sm
@@ -109,23 +109,27 @@ func NewBug(title string) *Bug {
 }
 
 func (b *Bug) Assign(assignee string) {
-	err := b.sm.Fire(Assign.Key, assignee)
+	err := b.sm.Fire(context.Background(), Assign.Key, assignee)
 	if err != nil {
 		fmt.Println("assign failed", err)
 	}
 }
 
-func (b *Bug) Deassigned() {
-	b.SendEmail(fmt.Sprintf("%s has been unassigned from you", b.Title))
+func (b *Bug) Deassigned(ctx context.Context) error {
+	return b.SendEmail(ctx, fmt.Sprintf("%s has been unassigned from you", b.Title))
 }
 
 func (b *Bug) Close() {
-	err := b.sm.Fire(Close.Key, nil)
+	err := b.sm.Fire(context.Background(), Close.Key)
 	if err != nil {
 		fmt.Println("close failed", err)
 	}
 }
 
-func (b *Bug) SendEmail(msg string) {
+func (b *Bug) SendEmail(ctx context.Context, msg string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	fmt.Printf("Sending Email => %s - %s\n", b.Assignee, msg)
+	return nil
 }
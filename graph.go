@@ -0,0 +1,148 @@
+package ssm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// ToGraph renders the configured state machine as a Graphviz DOT diagram. States are
+// shown as nodes, substates are nested inside clusters for their parent state, permitted
+// transitions are drawn as edges labeled with their trigger key, and guarded transitions
+// (registered via PermitIf) are drawn as dashed edges annotated with the names of their
+// guard predicates. The initial state is marked with an incoming arrow from a synthetic
+// start node, and the state the machine currently occupies is highlighted.
+func (sm *StateMachine) ToGraph() string {
+	children := make(map[State][]State)
+	var roots []State
+	for s, cfg := range sm.stateToConfig {
+		if cfg.parent == nil {
+			roots = append(roots, s)
+		} else {
+			children[cfg.parent.state] = append(children[cfg.parent.state], s)
+		}
+	}
+	sortStates(roots)
+	for s := range children {
+		sortStates(children[s])
+	}
+
+	var b strings.Builder
+	b.WriteString("digraph StateMachine {\n")
+	b.WriteString("\trankdir=LR;\n\n")
+	b.WriteString("\t__start [shape=point];\n")
+	b.WriteString(fmt.Sprintf("\t__start -> %s;\n\n", dotID(sm.initial.Name)))
+
+	for _, s := range roots {
+		b.WriteString(sm.renderState(s, children, "\t"))
+	}
+
+	b.WriteString("\n")
+	for _, s := range sortedStates(sm.stateToConfig) {
+		cfg := sm.stateToConfig[s]
+		for _, key := range sortedTriggerKeys(cfg.permitted) {
+			b.WriteString(renderEdge(s, cfg.permitted[key]))
+		}
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// renderState renders the node for s, and recursively renders its substates inside a
+// cluster subgraph when s has any.
+func (sm *StateMachine) renderState(s State, children map[State][]State, indent string) string {
+	kids := children[s]
+	if len(kids) == 0 {
+		return indent + sm.renderNode(s) + "\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%ssubgraph cluster_%s {\n", indent, sanitizeID(s.Name)))
+	b.WriteString(fmt.Sprintf("%s\tlabel=%q;\n", indent, s.Name))
+	b.WriteString(indent + "\t" + sm.renderNode(s) + "\n")
+	for _, k := range kids {
+		b.WriteString(sm.renderState(k, children, indent+"\t"))
+	}
+	b.WriteString(indent + "}\n")
+	return b.String()
+}
+
+// renderNode renders the node declaration for a single state, highlighting it if it is
+// the state machine's current state.
+func (sm *StateMachine) renderNode(s State) string {
+	if s == sm.current.state {
+		return fmt.Sprintf("%s [shape=box, style=filled, fillcolor=lightyellow];", dotID(s.Name))
+	}
+	return fmt.Sprintf("%s [shape=box];", dotID(s.Name))
+}
+
+// renderEdge renders a single permitted transition as a DOT edge, dashing the edge and
+// annotating it with the names of its guard predicates when the transition is guarded.
+func renderEdge(from State, e *edge) string {
+	label := e.trigger.Key
+	attrs := ""
+	if len(e.preds) > 0 {
+		names := make([]string, len(e.preds))
+		for i, pred := range e.preds {
+			names[i] = predicateName(pred)
+		}
+		label = fmt.Sprintf("%s [%s]", label, strings.Join(names, ", "))
+		attrs = ", style=dashed"
+	}
+	return fmt.Sprintf("\t%s -> %s [label=%q%s];\n", dotID(from.Name), dotID(e.state.Name), label, attrs)
+}
+
+// predicateName returns a best-effort human readable name for a guard predicate, derived
+// from the function's runtime symbol.
+func predicateName(pred func(ctx context.Context, args ...any) bool) string {
+	name := runtime.FuncForPC(reflect.ValueOf(pred).Pointer()).Name()
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return name
+}
+
+// dotID returns s quoted as a DOT identifier.
+func dotID(s string) string {
+	return fmt.Sprintf("%q", s)
+}
+
+// sanitizeID returns s with characters that are not valid in an unquoted DOT identifier
+// replaced, for use as a cluster name.
+func sanitizeID(s string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// sortStates sorts states in place by name for deterministic output.
+func sortStates(states []State) {
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+}
+
+// sortedStates returns the keys of m sorted by state name for deterministic output.
+func sortedStates(m map[State]*StateConfig) []State {
+	states := make([]State, 0, len(m))
+	for s := range m {
+		states = append(states, s)
+	}
+	sortStates(states)
+	return states
+}
+
+// sortedTriggerKeys returns the keys of m sorted for deterministic output.
+func sortedTriggerKeys(m map[string]*edge) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,33 @@
+package ssm_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/markdaws/simple-state-machine"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToGraph(t *testing.T) {
+	s1 := ssm.State{Name: "s1"}
+	s2 := ssm.State{Name: "s2"}
+	s3 := ssm.State{Name: "s3"}
+	tr1 := ssm.Trigger{Key: "tr1"}
+	tr2 := ssm.Trigger{Key: "tr2"}
+
+	sm := ssm.NewStateMachine(s1)
+	cfg := sm.Configure(s1)
+	cfg.Permit(tr1, s2)
+
+	cfg = sm.Configure(s2)
+	cfg.SubstateOf(s1)
+	cfg.PermitIf(tr2, s3, func(ctx context.Context, args ...any) bool { return true })
+
+	dot := sm.ToGraph()
+	require.Contains(t, dot, "digraph StateMachine {")
+	require.Contains(t, dot, `__start -> "s1";`)
+	require.Contains(t, dot, "subgraph cluster_s1")
+	require.Contains(t, dot, `"s1" -> "s2" [label="tr1"];`)
+	require.Contains(t, dot, "style=dashed")
+	require.Contains(t, dot, "fillcolor=lightyellow")
+}
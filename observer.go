@@ -0,0 +1,115 @@
+package ssm
+
+import (
+	"context"
+	"log/slog"
+)
+
+// NotificationKind identifies the kind of event a Notification describes.
+type NotificationKind int
+
+const (
+	// NotificationStateEntered is raised whenever Fire enters a state, including states
+	// entered via a cascading InitialTransition.
+	NotificationStateEntered NotificationKind = iota
+	// NotificationStateExited is raised whenever Fire exits a state on its way to a new one.
+	NotificationStateExited
+	// NotificationGuardFailed is raised when a trigger maps to a guarded transition but none
+	// of its predicates allow it.
+	NotificationGuardFailed
+	// NotificationUnhandledTrigger is raised when a trigger has no transition registered for
+	// the current state at all.
+	NotificationUnhandledTrigger
+)
+
+// String returns a human readable name for k.
+func (k NotificationKind) String() string {
+	switch k {
+	case NotificationStateEntered:
+		return "StateEntered"
+	case NotificationStateExited:
+		return "StateExited"
+	case NotificationGuardFailed:
+		return "GuardFailed"
+	case NotificationUnhandledTrigger:
+		return "UnhandledTrigger"
+	default:
+		return "Unknown"
+	}
+}
+
+// Notification describes a single event raised by Fire: a state being entered or exited, a
+// guard predicate rejecting a transition, or a trigger with no transition registered.
+type Notification struct {
+	Kind    NotificationKind
+	State   State
+	Trigger string
+	Args    []any
+}
+
+// Observer is notified of events raised during Fire. See StateMachine.RegisterObserver.
+type Observer interface {
+	Notify(ctx context.Context, n Notification)
+}
+
+// RegisterObserver attaches o so it is notified synchronously, in Fire's own goroutine, of
+// every state entry, state exit, guard failure, and unhandled trigger it raises. Any number
+// of observers may be registered; they are notified in the order they were added.
+func (sm *StateMachine) RegisterObserver(o Observer) *StateMachine {
+	sm.observers = append(sm.observers, o)
+	return sm
+}
+
+// notify dispatches n to every registered observer.
+func (sm *StateMachine) notify(ctx context.Context, n Notification) {
+	for _, o := range sm.observers {
+		o.Notify(ctx, n)
+	}
+}
+
+// SlogObserver is an Observer that writes each Notification as a structured log/slog record.
+type SlogObserver struct {
+	logger *slog.Logger
+}
+
+// NewSlogObserver returns a SlogObserver that logs to logger, or to slog.Default() if logger
+// is nil.
+func NewSlogObserver(logger *slog.Logger) *SlogObserver {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogObserver{logger: logger}
+}
+
+// Notify logs n at info level, or at warn level for guard failures and unhandled triggers.
+func (o *SlogObserver) Notify(ctx context.Context, n Notification) {
+	level := slog.LevelInfo
+	if n.Kind == NotificationGuardFailed || n.Kind == NotificationUnhandledTrigger {
+		level = slog.LevelWarn
+	}
+	o.logger.LogAttrs(ctx, level, n.Kind.String(),
+		slog.String("state", n.State.Name),
+		slog.String("trigger", n.Trigger),
+	)
+}
+
+// ChannelObserver is an Observer that pushes each Notification onto a channel, for streaming
+// state machine activity to a UI or test harness. Notify blocks until the notification is
+// delivered or ctx is done, so the channel should either be buffered or actively drained by
+// a concurrent reader.
+type ChannelObserver struct {
+	ch chan<- Notification
+}
+
+// NewChannelObserver returns a ChannelObserver that pushes notifications onto ch.
+func NewChannelObserver(ch chan<- Notification) *ChannelObserver {
+	return &ChannelObserver{ch: ch}
+}
+
+// Notify sends n on the observer's channel, or returns without sending if ctx is done first.
+func (o *ChannelObserver) Notify(ctx context.Context, n Notification) {
+	select {
+	case o.ch <- n:
+	case <-ctx.Done():
+	}
+}
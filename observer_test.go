@@ -0,0 +1,124 @@
+package ssm_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/markdaws/simple-state-machine"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingObserver struct {
+	notifications []ssm.Notification
+}
+
+func (o *recordingObserver) Notify(ctx context.Context, n ssm.Notification) {
+	o.notifications = append(o.notifications, n)
+}
+
+func TestRegisterObserverReceivesStateEntryAndExit(t *testing.T) {
+	s1 := ssm.State{Name: "s1"}
+	s2 := ssm.State{Name: "s2"}
+	tr := ssm.Trigger{Key: "tr"}
+
+	sm := ssm.NewStateMachine(s1)
+	cfg := sm.Configure(s1)
+	cfg.Permit(tr, s2)
+	sm.Configure(s2)
+
+	obs := &recordingObserver{}
+	sm.RegisterObserver(obs)
+
+	err := sm.Fire(context.Background(), tr.Key)
+	require.Nil(t, err)
+	require.Equal(t, []ssm.Notification{
+		{Kind: ssm.NotificationStateExited, State: s1, Trigger: tr.Key},
+		{Kind: ssm.NotificationStateEntered, State: s2, Trigger: tr.Key},
+	}, obs.notifications)
+}
+
+func TestRegisterObserverReceivesGuardFailedAndUnhandledTrigger(t *testing.T) {
+	s1 := ssm.State{Name: "s1"}
+	s2 := ssm.State{Name: "s2"}
+	tr1 := ssm.Trigger{Key: "tr1"}
+	tr2 := ssm.Trigger{Key: "tr2"}
+
+	sm := ssm.NewStateMachine(s1)
+	cfg := sm.Configure(s1)
+	cfg.PermitIf(tr1, s2, func(ctx context.Context, args ...any) bool { return false })
+
+	obs := &recordingObserver{}
+	sm.RegisterObserver(obs)
+
+	err := sm.Fire(context.Background(), tr1.Key)
+	require.NotNil(t, err)
+
+	err = sm.Fire(context.Background(), tr2.Key)
+	require.NotNil(t, err)
+
+	require.Equal(t, []ssm.Notification{
+		{Kind: ssm.NotificationGuardFailed, State: s1, Trigger: tr1.Key},
+		{Kind: ssm.NotificationUnhandledTrigger, State: s1, Trigger: tr2.Key},
+	}, obs.notifications)
+}
+
+func TestRegisterObserverReentrantSubstateDoesNotExitAncestor(t *testing.T) {
+	super := ssm.State{Name: "super"}
+	sub := ssm.State{Name: "sub"}
+	tr := ssm.Trigger{Key: "tr"}
+
+	sm := ssm.NewStateMachine(sub)
+	sm.Configure(super)
+	cfg := sm.Configure(sub)
+	cfg.SubstateOf(super)
+	cfg.Permit(tr, sub)
+
+	obs := &recordingObserver{}
+	sm.RegisterObserver(obs)
+
+	err := sm.Fire(context.Background(), tr.Key)
+	require.Nil(t, err)
+	require.Equal(t, []ssm.Notification{
+		{Kind: ssm.NotificationStateExited, State: sub, Trigger: tr.Key},
+		{Kind: ssm.NotificationStateEntered, State: sub, Trigger: tr.Key},
+	}, obs.notifications)
+}
+
+func TestRegisterObserverNoStateExitedOnOnExitError(t *testing.T) {
+	s1 := ssm.State{Name: "s1"}
+	s2 := ssm.State{Name: "s2"}
+	tr := ssm.Trigger{Key: "tr"}
+
+	sm := ssm.NewStateMachine(s1)
+	cfg := sm.Configure(s1)
+	cfg.Permit(tr, s2)
+	cfg.OnExit(func(ctx context.Context, args ...any) error { return errors.New("exit failed") })
+	sm.Configure(s2)
+
+	obs := &recordingObserver{}
+	sm.RegisterObserver(obs)
+
+	err := sm.Fire(context.Background(), tr.Key)
+	require.NotNil(t, err)
+	require.Empty(t, obs.notifications)
+}
+
+func TestChannelObserver(t *testing.T) {
+	s1 := ssm.State{Name: "s1"}
+	s2 := ssm.State{Name: "s2"}
+	tr := ssm.Trigger{Key: "tr"}
+
+	ch := make(chan ssm.Notification, 2)
+	sm := ssm.NewStateMachine(s1)
+	cfg := sm.Configure(s1)
+	cfg.Permit(tr, s2)
+	sm.Configure(s2)
+	sm.RegisterObserver(ssm.NewChannelObserver(ch))
+
+	err := sm.Fire(context.Background(), tr.Key)
+	require.Nil(t, err)
+
+	require.Equal(t, ssm.NotificationStateExited, (<-ch).Kind)
+	require.Equal(t, ssm.NotificationStateEntered, (<-ch).Kind)
+}
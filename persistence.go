@@ -0,0 +1,48 @@
+package ssm
+
+import "context"
+
+// Persister is implemented by external storage that holds the authoritative current state
+// for a StateMachine, such as a column on a Bug or job record in a database.
+type Persister interface {
+	// Load returns the currently persisted state.
+	Load(ctx context.Context) (State, error)
+	// Save persists s as the new current state.
+	Save(ctx context.Context, s State) error
+}
+
+// StateAccessor supplies the Load/Save callbacks NewStateMachine uses to read and write a
+// machine's current state: Fire loads it at the start and saves the new state after a
+// successful transition. Between calls to Fire the StateMachine keeps its own copy of the
+// last state it loaded or entered. Use NewPersisterAccessor to build one from a Persister.
+type StateAccessor struct {
+	Load func(ctx context.Context) (State, error)
+	Save func(ctx context.Context, s State) error
+}
+
+// NewPersisterAccessor returns a StateAccessor backed by p.
+func NewPersisterAccessor(p Persister) StateAccessor {
+	return StateAccessor{Load: p.Load, Save: p.Save}
+}
+
+// MemoryPersister is a Persister backed by an in-memory field. It is mainly useful for
+// tests, or as a stand-in until real storage is wired up.
+type MemoryPersister struct {
+	state State
+}
+
+// NewMemoryPersister returns a MemoryPersister initialized to s.
+func NewMemoryPersister(s State) *MemoryPersister {
+	return &MemoryPersister{state: s}
+}
+
+// Load returns the persister's current state.
+func (p *MemoryPersister) Load(ctx context.Context) (State, error) {
+	return p.state, nil
+}
+
+// Save stores s as the persister's current state.
+func (p *MemoryPersister) Save(ctx context.Context, s State) error {
+	p.state = s
+	return nil
+}
@@ -0,0 +1,54 @@
+package ssm_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/markdaws/simple-state-machine"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFireWithPersister(t *testing.T) {
+	s1 := ssm.State{Name: "s1"}
+	s2 := ssm.State{Name: "s2"}
+	tr := ssm.Trigger{Key: "tr"}
+
+	persister := ssm.NewMemoryPersister(s1)
+
+	sm := ssm.NewStateMachine(s1, ssm.NewPersisterAccessor(persister))
+	cfg := sm.Configure(s1)
+	cfg.Permit(tr, s2)
+	sm.Configure(s2)
+
+	err := sm.Fire(context.Background(), tr.Key)
+	require.Nil(t, err)
+	require.Equal(t, s2, sm.State())
+
+	loaded, err := persister.Load(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, s2, loaded)
+}
+
+func TestFireWithPersisterDoesNotSaveOnEnterError(t *testing.T) {
+	s1 := ssm.State{Name: "s1"}
+	s2 := ssm.State{Name: "s2"}
+	tr := ssm.Trigger{Key: "tr"}
+
+	persister := ssm.NewMemoryPersister(s1)
+
+	sm := ssm.NewStateMachine(s1, ssm.NewPersisterAccessor(persister))
+	cfg := sm.Configure(s1)
+	cfg.Permit(tr, s2)
+
+	wantErr := errors.New("enter failed")
+	cfg = sm.Configure(s2)
+	cfg.OnEnter(func(ctx context.Context, args ...any) error { return wantErr })
+
+	err := sm.Fire(context.Background(), tr.Key)
+	require.Equal(t, wantErr, err)
+
+	loaded, err := persister.Load(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, s1, loaded)
+}
@@ -1,21 +1,30 @@
 package ssm
 
+import (
+	"context"
+	"fmt"
+)
+
 // edge represents a transition edge from one state to another via a trigger.
 type edge struct {
 	trigger Trigger
 	state   State
-	preds   []func() bool
+	preds   []func(ctx context.Context, args ...any) bool
 }
 
 // StateConfig stores all of the config information for a state.
 type StateConfig struct {
 	owner       *StateMachine
-	onEnter     func()
-	onEnterFrom map[Trigger]func(interface{})
-	onExit      func()
+	onEnter     func(ctx context.Context, args ...any) error
+	onEnterFrom map[Trigger]func(ctx context.Context, args ...any) error
+	onExit      func(ctx context.Context, args ...any) error
 	state       State
 	parent      *StateConfig
 	permitted   map[string]*edge
+
+	// initialTransition is the substate, if any, that Fire automatically cascades into
+	// whenever this state is entered as the direct target of a transition.
+	initialTransition *State
 }
 
 // NewStateConfig returns an initialized StateConfig instance
@@ -23,7 +32,7 @@ func NewStateConfig(sm *StateMachine, s State) *StateConfig {
 	return &StateConfig{
 		owner:       sm,
 		state:       s,
-		onEnterFrom: make(map[Trigger]func(interface{})),
+		onEnterFrom: make(map[Trigger]func(ctx context.Context, args ...any) error),
 		permitted:   make(map[string]*edge),
 	}
 }
@@ -39,7 +48,7 @@ func (c *StateConfig) Permit(t Trigger, s State) *StateConfig {
 // PermitIf defines a relationship from one state to another via a trigger, which is valid
 // when the predicate function evaluates to true.  You can use this to say that we can transition
 // from one state to another via a trigger only under certain conditions
-func (c *StateConfig) PermitIf(t Trigger, s State, pred func() bool) *StateConfig {
+func (c *StateConfig) PermitIf(t Trigger, s State, pred func(ctx context.Context, args ...any) bool) *StateConfig {
 	c.owner.registerStateConfig(s)
 
 	val, ok := c.permitted[t.Key]
@@ -55,25 +64,42 @@ func (c *StateConfig) PermitIf(t Trigger, s State, pred func() bool) *StateConfi
 // OnEnter registers a handler that will be fired when the state is entered.  This is also fired for
 // re-entrant transitions where a state transitions to itself. This handler is called for all triggers
 // that enter a state, if you only want to perform an action entering a state for a certain trigger, then
-// use OnEnterFrom instead.
-func (c *StateConfig) OnEnter(f func()) *StateConfig {
+// use OnEnterFrom instead. The ctx and args passed to Fire are forwarded to the handler, and a non-nil
+// error return aborts the transition.
+func (c *StateConfig) OnEnter(f func(ctx context.Context, args ...any) error) *StateConfig {
 	c.onEnter = f
 	return c
 }
 
 // OnEnterFrom registers a handler that will fire when entering a state only via the specified trigger.
-func (c *StateConfig) OnEnterFrom(t Trigger, f func(interface{})) *StateConfig {
+// A non-nil error return aborts the transition.
+func (c *StateConfig) OnEnterFrom(t Trigger, f func(ctx context.Context, args ...any) error) *StateConfig {
 	c.onEnterFrom[t] = f
 	return c
 }
 
-// OnExit registers a handler that will fire when we exit a state. This will also for for re-entrant transitions
-// where we transition from a state to itself.
-func (c *StateConfig) OnExit(f func()) *StateConfig {
+// OnExit registers a handler that will fire when we exit a state. This will also fire for re-entrant transitions
+// where we transition from a state to itself. A non-nil error return aborts the transition before the machine
+// leaves the current state.
+func (c *StateConfig) OnExit(f func(ctx context.Context, args ...any) error) *StateConfig {
 	c.onExit = f
 	return c
 }
 
+// InitialTransition configures s as the substate the machine automatically cascades into
+// whenever this state is entered as the direct target of a transition, firing s's OnEnter
+// handler (and any further InitialTransition chain from s) immediately afterwards. s must
+// be registered as a substate of this state via SubstateOf; Fire panics if that is not the
+// case when it attempts the cascade, and InitialTransition itself panics if s is this state.
+func (c *StateConfig) InitialTransition(s State) *StateConfig {
+	if s == c.state {
+		panic(fmt.Sprintf("ssm: state %q cannot have an initial transition to itself", c.state.Name))
+	}
+	c.owner.registerStateConfig(s)
+	c.initialTransition = &s
+	return c
+}
+
 // SubstateOf specifies that a state is a substate of another.  This means you can specify that state B is a substate
 // of state A and if the state machine is currently in state B, asking IsInState(A) will return true ans well as IsInState(B).
 // This is also true for any depth of substate relationship.
@@ -1,8 +1,10 @@
 package ssm
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"reflect"
 )
 
 // State represents a single state of the system.
@@ -26,14 +28,33 @@ func (t Trigger) String() string {
 // and edges between states via triggers.  See the examples and test files for more examples.
 type StateMachine struct {
 	current       *StateConfig
+	initial       State
 	stateToConfig map[State]*StateConfig
+
+	onTransitioning    []func(Transition)
+	onTransitioned     []func(Transition)
+	onUnhandledTrigger func(state State, trigger string, args ...any) error
+
+	triggerParamTypes map[string]reflect.Type
+
+	accessor  *StateAccessor
+	observers []Observer
 }
 
-// NewStateMachine returns an initialized StateMachine instance.
-func NewStateMachine(initial State) *StateMachine {
+// NewStateMachine returns an initialized StateMachine instance. If a StateAccessor is
+// passed, the machine reads its current state from accessor.Load at the start of every Fire,
+// and writes the new state back via accessor.Save once the transition's OnEnter handlers
+// have succeeded. The machine still keeps an in-memory copy of the current state, refreshed
+// at each Fire: State, IsInState and CanFire read that copy and do not call accessor.Load,
+// and until the first Fire the copy is initial.
+func NewStateMachine(initial State, accessor ...StateAccessor) *StateMachine {
 	sm := &StateMachine{
+		initial:       initial,
 		stateToConfig: make(map[State]*StateConfig),
 	}
+	if len(accessor) > 0 {
+		sm.accessor = &accessor[0]
+	}
 	cfg := sm.registerStateConfig(initial)
 	sm.current = cfg
 	return sm
@@ -50,44 +71,148 @@ func (sm *StateMachine) State() State {
 	return sm.current.state
 }
 
-// Fire fires the specified trigger. If the trigger is not valid for the current
-// state an error is returned.
-func (sm *StateMachine) Fire(triggerKey string, ctx interface{}) error {
-	if !sm.CanFire(triggerKey) {
+// Fire fires the specified trigger, passing ctx and args through to every guard predicate
+// and OnEnter/OnExit/OnEnterFrom handler invoked along the way. If the trigger has no
+// transition registered for the current state at all, it is routed to the OnUnhandledTrigger
+// hook if one is registered, otherwise a generic error is returned; a trigger that is
+// registered but whose guard predicates all reject it always returns the generic error,
+// since it is a mapped trigger rather than an unhandled one. If the trigger was registered
+// via PermitTyped or OnEnterFromTyped, args is validated against its expected type before
+// any guard predicate or handler runs. If ctx is cancelled, or any handler returns a non-nil
+// error, Fire stops and returns that error. An error from an OnExit handler aborts the
+// transition before the machine leaves its current state. An error from OnEnterFrom or
+// OnEnter is returned to the caller, but since the exit handlers have already run by that
+// point the machine is left in the destination state in memory; with a StateAccessor that
+// state is not saved, so the next Fire loads the persisted source state again.
+func (sm *StateMachine) Fire(ctx context.Context, triggerKey string, args ...any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if sm.accessor != nil {
+		loaded, err := sm.accessor.Load(ctx)
+		if err != nil {
+			return err
+		}
+		cfg, ok := sm.stateToConfig[loaded]
+		if !ok {
+			return fmt.Errorf("ssm: persisted state %q is not configured", loaded.Name)
+		}
+		sm.current = cfg
+	}
+
+	if err := sm.checkTriggerArgs(triggerKey, args); err != nil {
+		return err
+	}
+
+	if !sm.CanFire(ctx, triggerKey, args...) {
+		kind := NotificationUnhandledTrigger
+		if _, ok := sm.current.permitted[triggerKey]; ok {
+			kind = NotificationGuardFailed
+		}
+		sm.notify(ctx, Notification{Kind: kind, State: sm.current.state, Trigger: triggerKey, Args: args})
+
+		if kind == NotificationUnhandledTrigger && sm.onUnhandledTrigger != nil {
+			return sm.onUnhandledTrigger(sm.current.state, triggerKey, args...)
+		}
 		return errors.New("unsupported trigger")
 	}
 
 	edge := sm.current.permitted[triggerKey]
 
-	// If the state we are transitioning to is not a substate of the current
-	// state then fire all of the exit handlers up the chain
-	targetParent := sm.stateToConfig[edge.state].parent
-	if targetParent == nil || (targetParent.state != sm.current.state) {
-		current := sm.current
-		for current != nil {
-			if current.onExit != nil {
-				current.onExit()
+	transition := Transition{Source: sm.current.state, Destination: edge.state, Trigger: triggerKey, Args: args}
+	for _, f := range sm.onTransitioning {
+		f(transition)
+	}
+
+	// Fire the exit handlers from the current state up to, but not including, the first
+	// ancestor that is also an ancestor of the destination state. That ancestor (and
+	// everything above it) stays active across the transition, so it does not exit - this
+	// also covers descending into a substate of the current state, where no ancestor exits
+	// at all, and still exits the state itself on a re-entrant transition into a substate.
+	stillActive := ancestorStates(sm.stateToConfig[edge.state])
+	current := sm.current
+	for current != nil && !stillActive[current.state] {
+		if current.onExit != nil {
+			if err := current.onExit(ctx, args...); err != nil {
+				return err
 			}
-			current = current.parent
 		}
+		sm.notify(ctx, Notification{Kind: NotificationStateExited, State: current.state, Trigger: triggerKey, Args: args})
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		current = current.parent
 	}
 
 	sm.current = sm.stateToConfig[edge.state]
 
 	enterFrom, ok := sm.current.onEnterFrom[edge.trigger]
 	if ok {
-		enterFrom(ctx)
+		if err := enterFrom(ctx, args...); err != nil {
+			return err
+		}
 	}
 
 	if sm.current.onEnter != nil {
-		sm.current.onEnter()
+		if err := sm.current.onEnter(ctx, args...); err != nil {
+			return err
+		}
+	}
+	sm.notify(ctx, Notification{Kind: NotificationStateEntered, State: sm.current.state, Trigger: triggerKey, Args: args})
+
+	if err := sm.descendInitialTransitions(ctx, args...); err != nil {
+		return err
+	}
+
+	if sm.accessor != nil {
+		if err := sm.accessor.Save(ctx, sm.current.state); err != nil {
+			return err
+		}
+	}
+
+	transition.Destination = sm.current.state
+	for _, f := range sm.onTransitioned {
+		f(transition)
+	}
+	return nil
+}
+
+// descendInitialTransitions cascades the machine into the chain of substates configured via
+// StateConfig.InitialTransition, firing each substate's OnEnter handler in order, starting
+// from the current state. It panics if a configured initial transition does not target an
+// actual substate of the state declaring it, or if the chain cycles back on itself.
+func (sm *StateMachine) descendInitialTransitions(ctx context.Context, args ...any) error {
+	visited := map[State]bool{sm.current.state: true}
+	for sm.current.initialTransition != nil {
+		target := *sm.current.initialTransition
+		targetCfg, ok := sm.stateToConfig[target]
+		if !ok || targetCfg.parent == nil || targetCfg.parent.state != sm.current.state {
+			panic(fmt.Sprintf("ssm: initial transition target %q is not a substate of %q", target.Name, sm.current.state.Name))
+		}
+		if visited[target] {
+			panic(fmt.Sprintf("ssm: initial transition cycle detected at state %q", target.Name))
+		}
+		visited[target] = true
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		sm.current = targetCfg
+		if sm.current.onEnter != nil {
+			if err := sm.current.onEnter(ctx, args...); err != nil {
+				return err
+			}
+		}
+		sm.notify(ctx, Notification{Kind: NotificationStateEntered, State: sm.current.state, Args: args})
 	}
 	return nil
 }
 
 // CanFire returns true if the specified trigger is valid for the State Machines
-// current state.
-func (sm *StateMachine) CanFire(triggerKey string) bool {
+// current state, evaluating any guard predicates with the supplied ctx and args.
+func (sm *StateMachine) CanFire(ctx context.Context, triggerKey string, args ...any) bool {
 	next, ok := sm.current.permitted[triggerKey]
 	if !ok {
 		return false
@@ -98,7 +223,7 @@ func (sm *StateMachine) CanFire(triggerKey string) bool {
 	if len(next.preds) > 0 {
 		found := false
 		for _, pred := range next.preds {
-			found = pred()
+			found = pred(ctx, args...)
 			if found {
 				break
 			}
@@ -123,6 +248,16 @@ func (sm *StateMachine) IsInState(s State) bool {
 	return false
 }
 
+// ancestorStates returns the set of states that are strict ancestors of cfg, i.e. cfg's
+// parent, its parent's parent, and so on, excluding cfg itself.
+func ancestorStates(cfg *StateConfig) map[State]bool {
+	ancestors := make(map[State]bool)
+	for p := cfg.parent; p != nil; p = p.parent {
+		ancestors[p.state] = true
+	}
+	return ancestors
+}
+
 // registerStateConfig registers the state with a blank configuration.
 func (sm *StateMachine) registerStateConfig(s State) *StateConfig {
 	cfg, ok := sm.stateToConfig[s]
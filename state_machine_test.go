@@ -1,6 +1,8 @@
 package ssm_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/markdaws/simple-state-machine"
@@ -25,15 +27,15 @@ func TestOnEnterOnExit(t *testing.T) {
 	s2ExitCalled := false
 
 	cfg := sm.Configure(s1)
-	cfg.OnEnter(func() { s1EnterCalled = true })
-	cfg.OnExit(func() { s1ExitCalled = true })
+	cfg.OnEnter(func(ctx context.Context, args ...any) error { s1EnterCalled = true; return nil })
+	cfg.OnExit(func(ctx context.Context, args ...any) error { s1ExitCalled = true; return nil })
 	cfg.Permit(tr, s2)
 
 	cfg = sm.Configure(s2)
-	cfg.OnEnter(func() { s2EnterCalled = true })
-	cfg.OnExit(func() { s2ExitCalled = true })
+	cfg.OnEnter(func(ctx context.Context, args ...any) error { s2EnterCalled = true; return nil })
+	cfg.OnExit(func(ctx context.Context, args ...any) error { s2ExitCalled = true; return nil })
 
-	err := sm.Fire(tr.Key, nil)
+	err := sm.Fire(context.Background(), tr.Key)
 	require.Nil(t, err)
 	require.False(t, s1EnterCalled)
 	require.True(t, s1ExitCalled)
@@ -54,7 +56,7 @@ func TestMultiplePermits(t *testing.T) {
 	cfg.Permit(tr1, s2)
 	cfg.Permit(tr2, s3)
 
-	err := sm.Fire(tr1.Key, nil)
+	err := sm.Fire(context.Background(), tr1.Key)
 	require.Nil(t, err)
 	require.Equal(t, sm.State(), s2)
 }
@@ -72,7 +74,7 @@ func TestMultiplePermitsPart2(t *testing.T) {
 	cfg.Permit(tr1, s2)
 	cfg.Permit(tr2, s3)
 
-	err := sm.Fire(tr2.Key, nil)
+	err := sm.Fire(context.Background(), tr2.Key)
 	require.Nil(t, err)
 	require.Equal(t, sm.State(), s3)
 }
@@ -87,7 +89,7 @@ func TestInvalidTrigger(t *testing.T) {
 	cfg := sm.Configure(s1)
 	cfg.Permit(tr1, s2)
 
-	err := sm.Fire(tr2.Key, nil)
+	err := sm.Fire(context.Background(), tr2.Key)
 	require.NotNil(t, err)
 	require.Equal(t, sm.State(), s1)
 }
@@ -101,17 +103,17 @@ func TestGuardedPermits(t *testing.T) {
 	cfg := sm.Configure(s1)
 
 	allow := false
-	cfg.PermitIf(tr1, s2, func() bool { return allow })
+	cfg.PermitIf(tr1, s2, func(ctx context.Context, args ...any) bool { return allow })
 
-	canFire := sm.CanFire(tr1.Key)
-	err := sm.Fire(tr1.Key, nil)
+	canFire := sm.CanFire(context.Background(), tr1.Key)
+	err := sm.Fire(context.Background(), tr1.Key)
 	require.False(t, canFire)
 	require.NotNil(t, err)
 	require.Equal(t, sm.State(), s1)
 
 	allow = true
-	canFire = sm.CanFire(tr1.Key)
-	err = sm.Fire(tr1.Key, nil)
+	canFire = sm.CanFire(context.Background(), tr1.Key)
+	err = sm.Fire(context.Background(), tr1.Key)
 	require.True(t, canFire)
 	require.Nil(t, err)
 	require.Equal(t, sm.State(), s2)
@@ -128,12 +130,12 @@ func TestOnEnterFrom(t *testing.T) {
 	cfg := sm.Configure(s3)
 	onEnter := false
 	onEnterFrom := false
-	cfg.OnEnterFrom(tr1, func(ctx interface{}) { onEnterFrom = true })
-	cfg.OnEnter(func() { onEnter = true })
+	cfg.OnEnterFrom(tr1, func(ctx context.Context, args ...any) error { onEnterFrom = true; return nil })
+	cfg.OnEnter(func(ctx context.Context, args ...any) error { onEnter = true; return nil })
 
 	cfg = sm.Configure(s1)
 	cfg.Permit(tr1, s3)
-	err := sm.Fire(tr1.Key, nil)
+	err := sm.Fire(context.Background(), tr1.Key)
 
 	require.Nil(t, err)
 	require.True(t, onEnter)
@@ -143,19 +145,19 @@ func TestOnEnterFrom(t *testing.T) {
 	cfg = sm.Configure(s3)
 	onEnter = false
 	onEnterFrom = false
-	cfg.OnEnterFrom(tr1, func(ctx interface{}) { onEnterFrom = true })
-	cfg.OnEnter(func() { onEnter = true })
+	cfg.OnEnterFrom(tr1, func(ctx context.Context, args ...any) error { onEnterFrom = true; return nil })
+	cfg.OnEnter(func(ctx context.Context, args ...any) error { onEnter = true; return nil })
 
 	cfg = sm.Configure(s2)
 	cfg.Permit(tr2, s3)
-	err = sm.Fire(tr2.Key, nil)
+	err = sm.Fire(context.Background(), tr2.Key)
 
 	require.Nil(t, err)
 	require.True(t, onEnter)
 	require.False(t, onEnterFrom)
 }
 
-func TestFireWithContext(t *testing.T) {
+func TestFireWithArgs(t *testing.T) {
 	s1 := ssm.State{Name: "s1"}
 	s2 := ssm.State{Name: "s2"}
 	tr := ssm.Trigger{Key: "tr"}
@@ -164,15 +166,193 @@ func TestFireWithContext(t *testing.T) {
 	cfg := sm.Configure(s1)
 	cfg.Permit(tr, s2)
 
-	var onEnterFrom interface{}
+	var gotArgs []any
 	cfg = sm.Configure(s2)
-	cfg.OnEnterFrom(tr, func(ctx interface{}) { onEnterFrom = ctx })
+	cfg.OnEnterFrom(tr, func(ctx context.Context, args ...any) error { gotArgs = args; return nil })
 
-	ctx := 12345
-	err := sm.Fire(tr.Key, ctx)
+	err := sm.Fire(context.Background(), tr.Key, 12345)
 
 	require.Nil(t, err)
-	require.Equal(t, ctx, onEnterFrom)
+	require.Equal(t, []any{12345}, gotArgs)
+}
+
+func TestFireWithCancelledContext(t *testing.T) {
+	s1 := ssm.State{Name: "s1"}
+	s2 := ssm.State{Name: "s2"}
+	tr := ssm.Trigger{Key: "tr"}
+
+	sm := ssm.NewStateMachine(s1)
+	cfg := sm.Configure(s1)
+	cfg.Permit(tr, s2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := sm.Fire(ctx, tr.Key)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, s1, sm.State())
+}
+
+func TestFireAbortsOnExitError(t *testing.T) {
+	s1 := ssm.State{Name: "s1"}
+	s2 := ssm.State{Name: "s2"}
+	tr := ssm.Trigger{Key: "tr"}
+
+	wantErr := errors.New("exit failed")
+	sm := ssm.NewStateMachine(s1)
+	cfg := sm.Configure(s1)
+	cfg.Permit(tr, s2)
+	cfg.OnExit(func(ctx context.Context, args ...any) error { return wantErr })
+
+	sm.Configure(s2)
+
+	err := sm.Fire(context.Background(), tr.Key)
+	require.Equal(t, wantErr, err)
+	require.Equal(t, s1, sm.State())
+}
+
+func TestFireAbortsOnEnterError(t *testing.T) {
+	s1 := ssm.State{Name: "s1"}
+	s2 := ssm.State{Name: "s2"}
+	tr := ssm.Trigger{Key: "tr"}
+
+	wantErr := errors.New("enter failed")
+	sm := ssm.NewStateMachine(s1)
+	cfg := sm.Configure(s1)
+	cfg.Permit(tr, s2)
+
+	cfg = sm.Configure(s2)
+	cfg.OnEnter(func(ctx context.Context, args ...any) error { return wantErr })
+
+	err := sm.Fire(context.Background(), tr.Key)
+	require.Equal(t, wantErr, err)
+	require.Equal(t, s2, sm.State())
+}
+
+func TestInitialTransition(t *testing.T) {
+	super := ssm.State{Name: "super"}
+	sub1 := ssm.State{Name: "sub1"}
+	sub2 := ssm.State{Name: "sub2"}
+	start := ssm.State{Name: "start"}
+	tr := ssm.Trigger{Key: "tr"}
+
+	var calls []string
+	sm := ssm.NewStateMachine(start)
+	cfg := sm.Configure(start)
+	cfg.Permit(tr, super)
+
+	cfg = sm.Configure(super)
+	cfg.InitialTransition(sub1)
+	cfg.OnEnter(func(ctx context.Context, args ...any) error { calls = append(calls, "super"); return nil })
+
+	cfg = sm.Configure(sub1)
+	cfg.SubstateOf(super)
+	cfg.InitialTransition(sub2)
+	cfg.OnEnter(func(ctx context.Context, args ...any) error { calls = append(calls, "sub1"); return nil })
+
+	cfg = sm.Configure(sub2)
+	cfg.SubstateOf(sub1)
+	cfg.OnEnter(func(ctx context.Context, args ...any) error { calls = append(calls, "sub2"); return nil })
+
+	err := sm.Fire(context.Background(), tr.Key)
+	require.Nil(t, err)
+	require.Equal(t, sub2, sm.State())
+	require.Equal(t, []string{"super", "sub1", "sub2"}, calls)
+}
+
+func TestInitialTransitionPanicsOnNonSubstate(t *testing.T) {
+	super := ssm.State{Name: "super"}
+	other := ssm.State{Name: "other"}
+	start := ssm.State{Name: "start"}
+	tr := ssm.Trigger{Key: "tr"}
+
+	sm := ssm.NewStateMachine(start)
+	cfg := sm.Configure(start)
+	cfg.Permit(tr, super)
+
+	cfg = sm.Configure(super)
+	cfg.InitialTransition(other)
+
+	require.Panics(t, func() { sm.Fire(context.Background(), tr.Key) })
+}
+
+func TestInitialTransitionPanicsOnSelfTarget(t *testing.T) {
+	super := ssm.State{Name: "super"}
+
+	sm := ssm.NewStateMachine(super)
+	cfg := sm.Configure(super)
+	require.Panics(t, func() { cfg.InitialTransition(super) })
+}
+
+func TestOnTransitioningAndOnTransitioned(t *testing.T) {
+	s1 := ssm.State{Name: "s1"}
+	s2 := ssm.State{Name: "s2"}
+	tr := ssm.Trigger{Key: "tr"}
+
+	var calls []string
+	sm := ssm.NewStateMachine(s1)
+	cfg := sm.Configure(s1)
+	cfg.Permit(tr, s2)
+	cfg.OnExit(func(ctx context.Context, args ...any) error { calls = append(calls, "exit"); return nil })
+
+	cfg = sm.Configure(s2)
+	cfg.OnEnter(func(ctx context.Context, args ...any) error { calls = append(calls, "enter"); return nil })
+
+	var transitioning, transitioned ssm.Transition
+	sm.OnTransitioning(func(tn ssm.Transition) { calls = append(calls, "transitioning"); transitioning = tn })
+	sm.OnTransitioned(func(tn ssm.Transition) { calls = append(calls, "transitioned"); transitioned = tn })
+
+	err := sm.Fire(context.Background(), tr.Key, "arg")
+	require.Nil(t, err)
+	require.Equal(t, []string{"transitioning", "exit", "enter", "transitioned"}, calls)
+	require.Equal(t, ssm.Transition{Source: s1, Destination: s2, Trigger: tr.Key, Args: []any{"arg"}}, transitioning)
+	require.Equal(t, ssm.Transition{Source: s1, Destination: s2, Trigger: tr.Key, Args: []any{"arg"}}, transitioned)
+}
+
+func TestOnUnhandledTrigger(t *testing.T) {
+	s1 := ssm.State{Name: "s1"}
+	s2 := ssm.State{Name: "s2"}
+	tr1 := ssm.Trigger{Key: "tr1"}
+	tr2 := ssm.Trigger{Key: "tr2"}
+
+	sm := ssm.NewStateMachine(s1)
+	cfg := sm.Configure(s1)
+	cfg.Permit(tr1, s2)
+
+	var gotState ssm.State
+	var gotTrigger string
+	wantErr := errors.New("no thanks")
+	sm.OnUnhandledTrigger(func(state ssm.State, trigger string, args ...any) error {
+		gotState = state
+		gotTrigger = trigger
+		return wantErr
+	})
+
+	err := sm.Fire(context.Background(), tr2.Key)
+	require.Equal(t, wantErr, err)
+	require.Equal(t, s1, gotState)
+	require.Equal(t, tr2.Key, gotTrigger)
+}
+
+func TestOnUnhandledTriggerNotInvokedForGuardFailure(t *testing.T) {
+	s1 := ssm.State{Name: "s1"}
+	s2 := ssm.State{Name: "s2"}
+	tr := ssm.Trigger{Key: "tr"}
+
+	sm := ssm.NewStateMachine(s1)
+	cfg := sm.Configure(s1)
+	cfg.PermitIf(tr, s2, func(ctx context.Context, args ...any) bool { return false })
+
+	hookCalled := false
+	sm.OnUnhandledTrigger(func(state ssm.State, trigger string, args ...any) error {
+		hookCalled = true
+		return nil
+	})
+
+	err := sm.Fire(context.Background(), tr.Key)
+	require.NotNil(t, err)
+	require.False(t, hookCalled)
+	require.Equal(t, s1, sm.State())
 }
 
 func TestReentry(t *testing.T) {
@@ -186,10 +366,10 @@ func TestReentry(t *testing.T) {
 	// case the enter/exit handlers should still be fired
 	var methods []string
 	cfg.Permit(tr, s1)
-	cfg.OnExit(func() { methods = append(methods, "exit") })
-	cfg.OnEnter(func() { methods = append(methods, "enter") })
+	cfg.OnExit(func(ctx context.Context, args ...any) error { methods = append(methods, "exit"); return nil })
+	cfg.OnEnter(func(ctx context.Context, args ...any) error { methods = append(methods, "enter"); return nil })
 
-	err := sm.Fire(tr.Key, nil)
+	err := sm.Fire(context.Background(), tr.Key)
 	require.Nil(t, err)
 	require.Equal(t, "exit", methods[0])
 	require.Equal(t, "enter", methods[1])
@@ -217,7 +397,7 @@ func TestIsInState(t *testing.T) {
 	cfg.Permit(tr3, s4)
 
 	require.True(t, sm.IsInState(s1))
-	err := sm.Fire(tr1.Key, nil)
+	err := sm.Fire(context.Background(), tr1.Key)
 	require.Nil(t, err)
 	require.Equal(t, s2, sm.State())
 	require.True(t, sm.IsInState(s2))
@@ -225,7 +405,7 @@ func TestIsInState(t *testing.T) {
 	// s2 is a substate of s1, so should still be considered in s1
 	require.True(t, sm.IsInState(s1))
 
-	err = sm.Fire(tr2.Key, nil)
+	err = sm.Fire(context.Background(), tr2.Key)
 	require.Nil(t, err)
 	require.Equal(t, s3, sm.State())
 	require.True(t, sm.IsInState(s1))
@@ -233,7 +413,7 @@ func TestIsInState(t *testing.T) {
 	require.True(t, sm.IsInState(s3))
 	require.False(t, sm.IsInState(s4))
 
-	err = sm.Fire(tr3.Key, nil)
+	err = sm.Fire(context.Background(), tr3.Key)
 	require.Nil(t, err)
 	require.Equal(t, s4, sm.State())
 	require.False(t, sm.IsInState(s1))
@@ -258,31 +438,31 @@ func TestOnEnterOnExitSubstates(t *testing.T) {
 	sm := ssm.NewStateMachine(s1)
 	cfg := sm.Configure(s1)
 	cfg.Permit(tr1, s2)
-	cfg.OnEnter(func() { calls = append(calls, "s1enter") })
-	cfg.OnExit(func() { calls = append(calls, "s1exit") })
+	cfg.OnEnter(func(ctx context.Context, args ...any) error { calls = append(calls, "s1enter"); return nil })
+	cfg.OnExit(func(ctx context.Context, args ...any) error { calls = append(calls, "s1exit"); return nil })
 
 	cfg = sm.Configure(s2)
 	cfg.Permit(tr2, s3)
-	cfg.OnEnter(func() { calls = append(calls, "s2enter") })
-	cfg.OnExit(func() { calls = append(calls, "s2exit") })
+	cfg.OnEnter(func(ctx context.Context, args ...any) error { calls = append(calls, "s2enter"); return nil })
+	cfg.OnExit(func(ctx context.Context, args ...any) error { calls = append(calls, "s2exit"); return nil })
 
 	cfg = sm.Configure(s3)
 	cfg.SubstateOf(s2)
 	cfg.Permit(tr3, s4)
-	cfg.OnEnter(func() { calls = append(calls, "s3enter") })
-	cfg.OnExit(func() { calls = append(calls, "s3exit") })
+	cfg.OnEnter(func(ctx context.Context, args ...any) error { calls = append(calls, "s3enter"); return nil })
+	cfg.OnExit(func(ctx context.Context, args ...any) error { calls = append(calls, "s3exit"); return nil })
 
 	cfg = sm.Configure(s4)
-	cfg.OnEnter(func() { calls = append(calls, "s4enter") })
-	cfg.OnExit(func() { calls = append(calls, "s4exit") })
+	cfg.OnEnter(func(ctx context.Context, args ...any) error { calls = append(calls, "s4enter"); return nil })
+	cfg.OnExit(func(ctx context.Context, args ...any) error { calls = append(calls, "s4exit"); return nil })
 
-	err := sm.Fire(tr1.Key, nil)
+	err := sm.Fire(context.Background(), tr1.Key)
 	require.Nil(t, err)
 
-	err = sm.Fire(tr2.Key, nil)
+	err = sm.Fire(context.Background(), tr2.Key)
 	require.Nil(t, err)
 
-	err = sm.Fire(tr3.Key, nil)
+	err = sm.Fire(context.Background(), tr3.Key)
 	require.Nil(t, err)
 
 	require.Equal(t, []string{"s1exit", "s2enter", "s3enter", "s3exit", "s2exit", "s4enter"}, calls)
@@ -301,12 +481,12 @@ func TestCanFire(t *testing.T) {
 	cfg.Permit(tr1, s2)
 
 	canTransition := false
-	cfg.PermitIf(tr3, s3, func() bool { return canTransition })
+	cfg.PermitIf(tr3, s3, func(ctx context.Context, args ...any) bool { return canTransition })
 
-	require.True(t, sm.CanFire(tr1.Key))
-	require.False(t, sm.CanFire(tr2.Key))
-	require.False(t, sm.CanFire(tr3.Key))
+	require.True(t, sm.CanFire(context.Background(), tr1.Key))
+	require.False(t, sm.CanFire(context.Background(), tr2.Key))
+	require.False(t, sm.CanFire(context.Background(), tr3.Key))
 
 	canTransition = true
-	require.True(t, sm.CanFire(tr3.Key))
+	require.True(t, sm.CanFire(context.Background(), tr3.Key))
 }
@@ -0,0 +1,37 @@
+package ssm
+
+// Transition describes a single state change driven by Fire: the state the machine is
+// moving from, the state it is moving to, the trigger key that caused the move, and the
+// args passed to Fire.
+type Transition struct {
+	Source      State
+	Destination State
+	Trigger     string
+	Args        []any
+}
+
+// OnTransitioning registers a machine-level hook that is called with the Transition before
+// any OnExit handler runs. Multiple hooks may be registered; they are called in the order
+// they were added.
+func (sm *StateMachine) OnTransitioning(f func(Transition)) *StateMachine {
+	sm.onTransitioning = append(sm.onTransitioning, f)
+	return sm
+}
+
+// OnTransitioned registers a machine-level hook that is called with the Transition once the
+// machine has finished entering its destination state (including any cascaded initial
+// transitions). Multiple hooks may be registered; they are called in the order they were added.
+func (sm *StateMachine) OnTransitioned(f func(Transition)) *StateMachine {
+	sm.onTransitioned = append(sm.onTransitioned, f)
+	return sm
+}
+
+// OnUnhandledTrigger registers the hook called when Fire is given a trigger that has no
+// transition registered for the current state, in place of the default "unsupported
+// trigger" error. A registered trigger whose guard predicates all reject it does not reach
+// the hook. Its return value becomes Fire's return value. Only one hook can be registered;
+// later calls replace earlier ones.
+func (sm *StateMachine) OnUnhandledTrigger(f func(state State, trigger string, args ...any) error) *StateMachine {
+	sm.onUnhandledTrigger = f
+	return sm
+}
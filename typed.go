@@ -0,0 +1,86 @@
+package ssm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// TriggerWithParameters wraps a Trigger with the Go type of the single argument Fire is
+// expected to pass for it, so that PermitTyped and OnEnterFromTyped can be used in place of
+// Permit and OnEnterFrom to get compile-time checked handler signatures, instead of having
+// to type-assert an any argument by hand inside the handler.
+type TriggerWithParameters[T any] struct {
+	Trigger
+}
+
+// NewTriggerWithParameters returns a TriggerWithParameters[T] for the trigger with the given key.
+func NewTriggerWithParameters[T any](key string) TriggerWithParameters[T] {
+	return TriggerWithParameters[T]{Trigger: Trigger{Key: key}}
+}
+
+// PermitTyped behaves like StateConfig.Permit, but also records the expected argument type
+// for t so that Fire rejects a mismatched argument with a descriptive error rather than
+// letting a handler panic on a bad type assertion.
+func PermitTyped[T any](c *StateConfig, t TriggerWithParameters[T], s State) *StateConfig {
+	c.owner.registerTriggerType(t.Trigger, reflect.TypeOf((*T)(nil)).Elem())
+	return c.Permit(t.Trigger, s)
+}
+
+// OnEnterFromTyped behaves like StateConfig.OnEnterFrom, but f receives the trigger's
+// argument already asserted to type T instead of any. It also records T as t's expected
+// argument type, as PermitTyped does.
+func OnEnterFromTyped[T any](c *StateConfig, t TriggerWithParameters[T], f func(ctx context.Context, arg T) error) *StateConfig {
+	c.owner.registerTriggerType(t.Trigger, reflect.TypeOf((*T)(nil)).Elem())
+	return c.OnEnterFrom(t.Trigger, func(ctx context.Context, args ...any) error {
+		arg, err := assertTriggerArg[T](t.Trigger, args)
+		if err != nil {
+			return err
+		}
+		return f(ctx, arg)
+	})
+}
+
+// assertTriggerArg asserts that args contains exactly one value of type T, returning a
+// descriptive error instead of panicking if it does not.
+func assertTriggerArg[T any](t Trigger, args []any) (T, error) {
+	var zero T
+	if len(args) != 1 {
+		return zero, fmt.Errorf("ssm: trigger %q expects exactly 1 argument of type %T, got %d", t.Key, zero, len(args))
+	}
+	v, ok := args[0].(T)
+	if !ok {
+		return zero, fmt.Errorf("ssm: trigger %q expects argument of type %T, got %T", t.Key, zero, args[0])
+	}
+	return v, nil
+}
+
+// registerTriggerType records the expected argument type for a trigger, used by Fire to
+// validate arguments before dispatching to any handler.
+func (sm *StateMachine) registerTriggerType(t Trigger, typ reflect.Type) {
+	if sm.triggerParamTypes == nil {
+		sm.triggerParamTypes = make(map[string]reflect.Type)
+	}
+	sm.triggerParamTypes[t.Key] = typ
+}
+
+// checkTriggerArgs validates args against the argument type registered for triggerKey, if
+// any. It returns a descriptive error, rather than letting a handler panic on a bad type
+// assertion, when the argument is missing or of the wrong type.
+func (sm *StateMachine) checkTriggerArgs(triggerKey string, args []any) error {
+	typ, ok := sm.triggerParamTypes[triggerKey]
+	if !ok {
+		return nil
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("ssm: trigger %q expects exactly 1 argument of type %s, got %d", triggerKey, typ, len(args))
+	}
+	if args[0] == nil || reflect.TypeOf(args[0]) != typ {
+		got := "nil"
+		if args[0] != nil {
+			got = reflect.TypeOf(args[0]).String()
+		}
+		return fmt.Errorf("ssm: trigger %q expects argument of type %s, got %s", triggerKey, typ, got)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package ssm_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/markdaws/simple-state-machine"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermitTypedAndOnEnterFromTyped(t *testing.T) {
+	s1 := ssm.State{Name: "s1"}
+	s2 := ssm.State{Name: "s2"}
+	tr := ssm.NewTriggerWithParameters[string]("assign")
+
+	sm := ssm.NewStateMachine(s1)
+	cfg := sm.Configure(s1)
+	ssm.PermitTyped(cfg, tr, s2)
+
+	var got string
+	cfg = sm.Configure(s2)
+	ssm.OnEnterFromTyped(cfg, tr, func(ctx context.Context, arg string) error {
+		got = arg
+		return nil
+	})
+
+	err := sm.Fire(context.Background(), tr.Key, "frank")
+	require.Nil(t, err)
+	require.Equal(t, "frank", got)
+}
+
+func TestPermitTypedRejectsWrongArgType(t *testing.T) {
+	s1 := ssm.State{Name: "s1"}
+	s2 := ssm.State{Name: "s2"}
+	tr := ssm.NewTriggerWithParameters[string]("assign")
+
+	sm := ssm.NewStateMachine(s1)
+	cfg := sm.Configure(s1)
+	ssm.PermitTyped(cfg, tr, s2)
+	sm.Configure(s2)
+
+	err := sm.Fire(context.Background(), tr.Key, 12345)
+	require.NotNil(t, err)
+	require.Equal(t, s1, sm.State())
+}
+
+func TestPermitTypedRejectsMissingArg(t *testing.T) {
+	s1 := ssm.State{Name: "s1"}
+	s2 := ssm.State{Name: "s2"}
+	tr := ssm.NewTriggerWithParameters[string]("assign")
+
+	sm := ssm.NewStateMachine(s1)
+	cfg := sm.Configure(s1)
+	ssm.PermitTyped(cfg, tr, s2)
+	sm.Configure(s2)
+
+	err := sm.Fire(context.Background(), tr.Key)
+	require.NotNil(t, err)
+	require.Equal(t, s1, sm.State())
+}
+
+func TestPermitTypedRejectsWrongArgTypeBeforeGuardRuns(t *testing.T) {
+	s1 := ssm.State{Name: "s1"}
+	s2 := ssm.State{Name: "s2"}
+	tr := ssm.NewTriggerWithParameters[string]("assign")
+
+	sm := ssm.NewStateMachine(s1)
+	cfg := sm.Configure(s1)
+	ssm.PermitTyped(cfg, tr, s2)
+	guardCalled := false
+	cfg.PermitIf(tr.Trigger, s2, func(ctx context.Context, args ...any) bool {
+		guardCalled = true
+		return args[0].(string) != ""
+	})
+	sm.Configure(s2)
+
+	err := sm.Fire(context.Background(), tr.Key, 12345)
+	require.NotNil(t, err)
+	require.False(t, guardCalled)
+}